@@ -0,0 +1,80 @@
+// Copyright 2024 Ismo Vuorinen. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+// SPDX-License-Identifier: MIT
+
+//go:build go1.22
+// +build go1.22
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// CacheEntry records the conditional-GET state for a single feed URL, plus
+// the TTL hint (if any) that the feed itself advertised on the last fetch.
+type CacheEntry struct {
+	ETag         string        `json:"etag,omitempty"`
+	LastModified string        `json:"last_modified,omitempty"`
+	FetchedAt    time.Time     `json:"fetched_at"`
+	TTL          time.Duration `json:"ttl,omitempty"`
+
+	// Title is the feed's own title, as discovered on the last successful
+	// fetch. Used by OPML export so it doesn't need to re-fetch feeds just
+	// to label them.
+	Title string `json:"title,omitempty"`
+
+	// Articles are the feed's articles as of the last successful fetch.
+	// When a run skips this feed (a 304 response, or the TTL not yet
+	// elapsed), these are carried forward into that run's digest instead
+	// of the feed contributing nothing.
+	Articles []Article `json:"articles,omitempty"`
+}
+
+// FeedCache maps a feed URL to its last known conditional-GET state.
+type FeedCache map[string]CacheEntry
+
+// loadCache reads a FeedCache from path, returning an empty cache if the
+// file does not exist yet.
+func loadCache(path string) (FeedCache, error) {
+	cache := make(FeedCache)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cache, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, err
+	}
+
+	return cache, nil
+}
+
+// save writes the FeedCache to path as indented JSON.
+func (c FeedCache) save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// dueForFetch reports whether the feed at url should be fetched again,
+// honoring the TTL recorded from a previous run. Feeds with no cache entry
+// or no known TTL are always due.
+func (c FeedCache) dueForFetch(url string, now time.Time) bool {
+	entry, ok := c[url]
+	if !ok || entry.TTL <= 0 {
+		return true
+	}
+
+	return now.Sub(entry.FetchedAt) >= entry.TTL
+}
@@ -0,0 +1,47 @@
+// Copyright 2024 Ismo Vuorinen. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+// SPDX-License-Identifier: MIT
+
+//go:build go1.22
+// +build go1.22
+
+package main
+
+import "gopkg.in/yaml.v3"
+
+// FeedSpec is a single entry in Config.Feeds. A YAML entry may be either a
+// bare URL string (the original, flat format) or a mapping carrying
+// per-feed metadata; see UnmarshalYAML.
+type FeedSpec struct {
+	URL      string   `yaml:"url"`
+	Title    string   `yaml:"title,omitempty"`
+	Tags     []string `yaml:"tags,omitempty"`
+	Category string   `yaml:"category,omitempty"`
+
+	// MaxItems caps how many of this feed's articles are kept per run.
+	// Zero means unlimited.
+	MaxItems int `yaml:"max_items,omitempty"`
+}
+
+// UnmarshalYAML accepts either a bare URL string:
+//
+//	feeds:
+//	  - https://example.com/feed.xml
+//
+// or a mapping with additional metadata:
+//
+//	feeds:
+//	  - url: https://example.com/feed.xml
+//	    category: news
+//	    tags: [tech]
+//
+// so that existing flat configs keep working unchanged.
+func (f *FeedSpec) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&f.URL)
+	}
+
+	type rawFeedSpec FeedSpec
+	return value.Decode((*rawFeedSpec)(f))
+}
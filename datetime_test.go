@@ -0,0 +1,98 @@
+// Copyright 2024 Ismo Vuorinen. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+// SPDX-License-Identifier: MIT
+
+//go:build go1.22
+// +build go1.22
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  time.Time
+	}{
+		{
+			name:  "RFC1123Z",
+			value: "Mon, 02 Jan 2006 15:04:05 -0700",
+			want:  time.Date(2006, time.January, 2, 15, 4, 5, 0, time.FixedZone("", -7*60*60)),
+		},
+		{
+			name:  "RFC1123",
+			value: "Mon, 02 Jan 2006 15:04:05 MST",
+			want:  time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC),
+		},
+		{
+			name:  "RFC822Z",
+			value: "02 Jan 06 15:04 -0700",
+			want:  time.Date(2006, time.January, 2, 15, 4, 0, 0, time.FixedZone("", -7*60*60)),
+		},
+		{
+			name:  "RFC822",
+			value: "02 Jan 06 15:04 MST",
+			want:  time.Date(2006, time.January, 2, 15, 4, 0, 0, time.UTC),
+		},
+		{
+			name:  "RFC3339",
+			value: "2006-01-02T15:04:05-07:00",
+			want:  time.Date(2006, time.January, 2, 15, 4, 5, 0, time.FixedZone("", -7*60*60)),
+		},
+		{
+			name:  "RFC3339Nano",
+			value: "2006-01-02T15:04:05.999999999-07:00",
+			want:  time.Date(2006, time.January, 2, 15, 4, 5, 999999999, time.FixedZone("", -7*60*60)),
+		},
+		{
+			name:  "ANSIC",
+			value: "Mon Jan  2 15:04:05 2006",
+			want:  time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC),
+		},
+		{
+			name:  "UnixDate",
+			value: "Mon Jan  2 15:04:05 MST 2006",
+			want:  time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC),
+		},
+		{
+			name:  "space-separated with offset",
+			value: "2006-01-02 15:04:05 -0700",
+			want:  time.Date(2006, time.January, 2, 15, 4, 5, 0, time.FixedZone("", -7*60*60)),
+		},
+		{
+			name:  "date and time without offset",
+			value: "2006-01-02T15:04:05",
+			want:  time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC),
+		},
+		{
+			name:  "date only",
+			value: "2006-01-02",
+			want:  time.Date(2006, time.January, 2, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTime(tt.value)
+			if err != nil {
+				t.Fatalf("parseTime(%q) returned error: %v", tt.value, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseTime(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTimeUnrecognized(t *testing.T) {
+	for _, value := range []string{"", "not a date", "yesterday"} {
+		if _, err := parseTime(value); err == nil {
+			t.Errorf("parseTime(%q) expected an error, got nil", value)
+		}
+	}
+}
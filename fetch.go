@@ -0,0 +1,394 @@
+// Copyright 2024 Ismo Vuorinen. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+// SPDX-License-Identifier: MIT
+
+//go:build go1.22
+// +build go1.22
+
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/mmcdole/gofeed/rss"
+)
+
+// feedTimeout bounds how long a single feed fetch may take.
+const feedTimeout = 30 * time.Second
+
+// defaultFeedTTL is used when a feed advertises no cache-timeout hint of
+// its own, so frequently-polled feeds aren't re-fetched on every run.
+const defaultFeedTTL = 15 * time.Minute
+
+// fetchResult is the outcome of fetching a single feed, passed back from a
+// worker goroutine to the collector in main.
+type fetchResult struct {
+	url      string
+	articles []Article
+	entry    CacheEntry
+	skipped  bool
+	err      error
+}
+
+// parsedFeed is a format-neutral view of a feed, produced by either the
+// gofeed (RSS/Atom)-backed parser or the JSON Feed decoder.
+type parsedFeed struct {
+	Title string
+	Link  string
+	TTL   time.Duration
+	Items []parsedItem
+}
+
+// parsedItem is a format-neutral view of a single feed entry.
+type parsedItem struct {
+	Title       string
+	Link        string
+	PublishedAt *time.Time
+	Summary     string
+
+	// Author is the item's byline, when the feed format carries one (JSON
+	// Feed's authors[0].name). Empty for formats that don't.
+	Author string
+}
+
+// fetchArticles fetches and parses a single feed, dispatching to a local
+// file read for bare paths and `file://` URLs, or an HTTP conditional GET
+// otherwise. If the server responds 304 Not Modified, it returns
+// skipped=true and no articles, carrying the previous cache entry forward
+// with a refreshed fetch timestamp.
+func fetchArticles(client *http.Client, feedURL string, prev CacheEntry) ([]Article, CacheEntry, bool, error) {
+	if path, ok := localFeedPath(feedURL); ok {
+		return fetchLocalArticles(path, prev)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), feedTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, CacheEntry{}, false, fmt.Errorf("error building request: %w", err)
+	}
+	if prev.ETag != "" {
+		req.Header.Set("If-None-Match", prev.ETag)
+	}
+	if prev.LastModified != "" {
+		req.Header.Set("If-Modified-Since", prev.LastModified)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, CacheEntry{}, false, fmt.Errorf("error fetching feed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		entry := prev
+		entry.FetchedAt = time.Now().UTC()
+		return prev.Articles, entry, true, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, CacheEntry{}, false, fmt.Errorf("unexpected status fetching feed: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, CacheEntry{}, false, fmt.Errorf("error reading feed body: %w", err)
+	}
+
+	parsed, err := parseFeed(body, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, CacheEntry{}, false, err
+	}
+
+	articles := articlesFromParsedFeed(parsed)
+	entry := CacheEntry{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		FetchedAt:    time.Now().UTC(),
+		TTL:          parsed.TTL,
+		Title:        parsed.Title,
+		Articles:     articles,
+	}
+
+	return articles, entry, false, nil
+}
+
+// localFeedPath reports whether feedURL refers to the local filesystem
+// (a bare path, or a `file://` URL) and, if so, returns the path to read.
+func localFeedPath(feedURL string) (string, bool) {
+	if path, ok := strings.CutPrefix(feedURL, "file://"); ok {
+		return path, true
+	}
+	if strings.Contains(feedURL, "://") {
+		return "", false
+	}
+
+	return feedURL, true
+}
+
+// fetchLocalArticles reads and parses a feed from the local filesystem.
+// Since there's no ETag/Last-Modified to negotiate, it participates in the
+// cache using the file's modification time as the change indicator.
+func fetchLocalArticles(path string, prev CacheEntry) ([]Article, CacheEntry, bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, CacheEntry{}, false, fmt.Errorf("error statting local feed: %w", err)
+	}
+
+	mtime := info.ModTime().UTC().Format(time.RFC3339Nano)
+	if prev.ETag == mtime {
+		entry := prev
+		entry.FetchedAt = time.Now().UTC()
+		return prev.Articles, entry, true, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, CacheEntry{}, false, fmt.Errorf("error reading local feed: %w", err)
+	}
+
+	parsed, err := parseFeed(data, "")
+	if err != nil {
+		return nil, CacheEntry{}, false, err
+	}
+
+	articles := articlesFromParsedFeed(parsed)
+	entry := CacheEntry{
+		ETag:      mtime,
+		FetchedAt: time.Now().UTC(),
+		TTL:       parsed.TTL,
+		Title:     parsed.Title,
+		Articles:  articles,
+	}
+
+	return articles, entry, false, nil
+}
+
+// parseFeed sniffs whether body is a JSON Feed or an RSS/Atom feed, by
+// Content-Type and by the first non-whitespace byte, and dispatches to the
+// matching parser.
+func parseFeed(body []byte, contentType string) (*parsedFeed, error) {
+	if isJSONFeed(body, contentType) {
+		return parseJSONFeed(body)
+	}
+
+	return parseXMLFeed(body)
+}
+
+// isJSONFeed reports whether body looks like JSON Feed content.
+func isJSONFeed(body []byte, contentType string) bool {
+	if strings.Contains(contentType, "json") {
+		return true
+	}
+
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
+
+// parseXMLFeed parses an RSS or Atom feed via gofeed.
+func parseXMLFeed(body []byte) (*parsedFeed, error) {
+	fp := gofeed.NewParser()
+	feed, err := fp.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing feed: %v", err)
+	}
+
+	parsed := &parsedFeed{
+		Title: feed.Title,
+		Link:  feed.Link,
+		TTL:   feedCacheTTL(feed, body),
+	}
+
+	for _, item := range feed.Items {
+		publishedAt, ok := resolveItemDate(item)
+		if !ok {
+			log.Printf("Skipping item %q from feed %q: no parsable publish date", item.Title, feed.Title)
+			continue
+		}
+
+		parsed.Items = append(parsed.Items, parsedItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			PublishedAt: &publishedAt,
+			Summary:     cmp.Or(item.Description, item.Content),
+		})
+	}
+
+	return parsed, nil
+}
+
+// resolveItemDate determines an item's publish date, falling back through
+// gofeed's own parsed fields and a wider cascade of layouts (via
+// parseTime) when the feed uses a date format gofeed doesn't recognize.
+// It reports false when no date can be resolved at all, rather than
+// falling back to time.Now(): a fabricated "now" would misplace the item
+// in date-sorted output and in day/week/month grouping, so dropping it
+// with a log line is the safer failure mode.
+func resolveItemDate(item *gofeed.Item) (time.Time, bool) {
+	if item.PublishedParsed != nil {
+		return item.PublishedParsed.UTC(), true
+	}
+	if t, err := parseTime(item.Published); err == nil {
+		return t.UTC(), true
+	}
+	if item.UpdatedParsed != nil {
+		return item.UpdatedParsed.UTC(), true
+	}
+	if t, err := parseTime(item.Updated); err == nil {
+		return t.UTC(), true
+	}
+
+	return time.Time{}, false
+}
+
+// articlesFromParsedFeed converts a parsedFeed's items into Articles,
+// skipping items with no resolvable publish date.
+func articlesFromParsedFeed(parsed *parsedFeed) []Article {
+	var articles []Article
+	for _, item := range parsed.Items {
+		if item.PublishedAt == nil {
+			log.Printf("Skipping item %q from feed %q: no parsable publish date", item.Title, parsed.Title)
+			continue
+		}
+
+		articles = append(articles, Article{
+			Title:       item.Title,
+			Link:        item.Link,
+			Date:        *item.PublishedAt,
+			URLDomain:   getURLDomain(item.Link),
+			SourceTitle: parsed.Title,
+			SourceLink:  parsed.Link,
+			Summary:     item.Summary,
+			Author:      item.Author,
+		})
+	}
+
+	return articles
+}
+
+// feedCacheTTL extracts a cache lifetime hint from the RSS <ttl> element or
+// the RSS "sy" updateFrequency/updatePeriod extension, falling back to
+// defaultFeedTTL when the feed advertises neither.
+func feedCacheTTL(feed *gofeed.Feed, body []byte) time.Duration {
+	if feed.FeedType == "rss" {
+		if ttl, ok := rssTTL(body); ok {
+			return ttl
+		}
+	}
+
+	if ttl, ok := syTTL(feed); ok {
+		return ttl
+	}
+
+	return defaultFeedTTL
+}
+
+// rssTTL re-parses body with the underlying rss.Parser to read the <ttl>
+// element (the number of minutes the feed may be cached for), since
+// gofeed's universal Feed translation drops it.
+func rssTTL(body []byte) (time.Duration, bool) {
+	rssFeed, err := (&rss.Parser{}).Parse(bytes.NewReader(body))
+	if err != nil || rssFeed.TTL == "" {
+		return 0, false
+	}
+
+	minutes, err := strconv.Atoi(strings.TrimSpace(rssFeed.TTL))
+	if err != nil || minutes <= 0 {
+		return 0, false
+	}
+
+	return time.Duration(minutes) * time.Minute, true
+}
+
+// syTTL computes a cache lifetime from the RSS syndication module's
+// sy:updatePeriod (hourly/daily/weekly/monthly/yearly) and sy:updateFrequency
+// (the number of updates per period), per the sy module spec. Reports false
+// when the feed carries neither element.
+func syTTL(feed *gofeed.Feed) (time.Duration, bool) {
+	periodStr, hasPeriod := extensionValue(feed, "sy", "updatePeriod")
+	freqStr, hasFreq := extensionValue(feed, "sy", "updateFrequency")
+	if !hasPeriod && !hasFreq {
+		return 0, false
+	}
+
+	period := strings.ToLower(strings.TrimSpace(periodStr))
+	if !hasPeriod {
+		period = "daily" // the sy module's own default when omitted
+	}
+
+	var periodDuration time.Duration
+	switch period {
+	case "hourly":
+		periodDuration = time.Hour
+	case "daily":
+		periodDuration = 24 * time.Hour
+	case "weekly":
+		periodDuration = 7 * 24 * time.Hour
+	case "monthly":
+		periodDuration = 30 * 24 * time.Hour
+	case "yearly":
+		periodDuration = 365 * 24 * time.Hour
+	default:
+		return 0, false
+	}
+
+	freq := 1
+	if hasFreq {
+		if n, err := strconv.Atoi(strings.TrimSpace(freqStr)); err == nil && n > 0 {
+			freq = n
+		}
+	}
+
+	return periodDuration / time.Duration(freq), true
+}
+
+// extensionValue returns the first value of a feed extension element, if
+// present.
+func extensionValue(feed *gofeed.Feed, namespace, name string) (string, bool) {
+	if feed == nil || feed.Extensions == nil {
+		return "", false
+	}
+
+	elems, ok := feed.Extensions[namespace][name]
+	if !ok || len(elems) == 0 {
+		return "", false
+	}
+
+	return elems[0].Value, true
+}
+
+// applyFeedSpec stamps a feed's per-feed metadata (title override,
+// category, tags) onto each of its articles, then caps the result at
+// spec.MaxItems, keeping the most recent ones.
+func applyFeedSpec(articles []Article, spec FeedSpec) []Article {
+	for i := range articles {
+		if spec.Title != "" {
+			articles[i].SourceTitle = spec.Title
+		}
+		articles[i].Category = spec.Category
+		articles[i].Tags = spec.Tags
+	}
+
+	if spec.MaxItems <= 0 || len(articles) <= spec.MaxItems {
+		return articles
+	}
+
+	slices.SortFunc(articles, func(a, b Article) int {
+		return cmp.Compare(b.Date.Unix(), a.Date.Unix())
+	})
+
+	return articles[:spec.MaxItems]
+}
@@ -0,0 +1,94 @@
+// Copyright 2024 Ismo Vuorinen. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+// SPDX-License-Identifier: MIT
+
+//go:build go1.22
+// +build go1.22
+
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// groupArticles buckets articles into named groups according to groupBy,
+// returning the groups alongside their IDs in display order (most recent
+// first for the time-based modes, alphabetical otherwise). An article can
+// land in more than one group under "tag" mode.
+func groupArticles(groupBy string, articles []Article) (map[string][]Article, []string) {
+	groups := make(map[string][]Article)
+	var ids []string
+
+	for _, article := range articles {
+		for _, id := range groupKeys(groupBy, article) {
+			if _, ok := groups[id]; !ok {
+				ids = append(ids, id)
+			}
+			groups[id] = append(groups[id], article)
+		}
+	}
+
+	sortGroupIDs(groupBy, ids)
+
+	return groups, ids
+}
+
+// groupKeys returns the group ID(s) article belongs to for a given GroupBy
+// mode. Every mode but "tag" returns exactly one ID.
+func groupKeys(groupBy string, article Article) []string {
+	switch groupBy {
+	case "day":
+		return []string{article.Date.UTC().Format("2006-01-02")}
+	case "month":
+		return []string{article.Date.UTC().Format("2006-01")}
+	case "category":
+		return []string{cmp.Or(article.Category, "uncategorized")}
+	case "tag":
+		if len(article.Tags) == 0 {
+			return []string{"untagged"}
+		}
+		return article.Tags
+	case "source":
+		return []string{cmp.Or(article.SourceTitle, article.URLDomain)}
+	case "none":
+		return []string{""}
+	case "", "week":
+		fallthrough
+	default:
+		year, week := article.Date.UTC().ISOWeek()
+		return []string{fmt.Sprintf("%d-%02d", year, week)}
+	}
+}
+
+// sortGroupIDs orders group IDs for display: descending for the
+// chronological modes (most recent period first, matching the original
+// week behavior), ascending for everything else.
+func sortGroupIDs(groupBy string, ids []string) {
+	sort.Strings(ids)
+
+	switch groupBy {
+	case "category", "tag", "source":
+		return
+	default:
+		slices.Reverse(ids)
+	}
+}
+
+// groupHeading formats the Markdown heading for a group ID under groupBy.
+// An empty ID (the single "none"-mode group) gets no heading at all.
+func groupHeading(groupBy, id string) string {
+	if id == "" {
+		return ""
+	}
+
+	switch groupBy {
+	case "", "week":
+		return fmt.Sprintf("## Week: %s\n\n", id)
+	default:
+		return fmt.Sprintf("## %s\n\n", id)
+	}
+}
@@ -13,17 +13,18 @@ package main
 
 import (
 	"cmp"
+	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"net/url"
 	"os"
 	"regexp"
 	"slices"
-	"sort"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/mmcdole/gofeed"
 	"gopkg.in/yaml.v3"
 )
 
@@ -36,20 +37,92 @@ var (
 
 // Config represents the structure of the YAML configuration file
 type Config struct {
-	Template string   `yaml:"template"`
-	Output   string   `yaml:"output"`
-	Feeds    []string `yaml:"feeds"`
+	Template string     `yaml:"template"`
+	Output   string     `yaml:"output"`
+	Feeds    []FeedSpec `yaml:"feeds"`
+
+	// Concurrency caps how many feeds are fetched in parallel. Defaults to
+	// defaultConcurrency when unset or non-positive.
+	Concurrency int `yaml:"concurrency"`
+
+	// CacheFile is where ETag/Last-Modified state is persisted between
+	// runs. Defaults to defaultCacheFile when unset.
+	CacheFile string `yaml:"cache_file"`
+
+	// OpenRing enables an alternate output mode that samples the N most
+	// recent articles across all feeds (capped at P per source) instead of
+	// grouping by ISO week.
+	OpenRing OpenRingConfig `yaml:"openring"`
+
+	// GroupBy selects how generateMarkdown buckets articles: "week"
+	// (default), "day", "month", "category", "tag", "source", or "none"
+	// (a single, ungrouped list).
+	GroupBy string `yaml:"group_by"`
 }
 
-// Article represents a feed article
+// OpenRingConfig configures openring-style digest generation, named after
+// the similar tool in the sourcehut/openring ecosystem.
+type OpenRingConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// Template is an html/template file rendered with a single
+	// `.Articles []Article` field.
+	Template string `yaml:"template"`
+
+	// Count is the total number of articles to include (N).
+	Count int `yaml:"count"`
+
+	// PerSource caps how many of those articles may come from a single
+	// feed (P).
+	PerSource int `yaml:"per_source"`
+
+	// SummaryLength caps Article.Summary at this many runes.
+	SummaryLength int `yaml:"summary_length"`
+}
+
+// defaultConcurrency is the number of feeds fetched in parallel when
+// Config.Concurrency is unset.
+const defaultConcurrency = 4
+
+// defaultCacheFile is where conditional-GET state is persisted when
+// Config.CacheFile is unset.
+const defaultCacheFile = ".paperboy-cache.json"
+
+// Article represents a feed article. Field names match what the openring
+// template contract documents: Title, Link, SourceLink, SourceTitle, Date,
+// Summary.
 type Article struct {
-	PublishAt time.Time
+	Date      time.Time
 	Title     string
-	URL       string
+	Link      string
 	URLDomain string
+
+	// SourceTitle and SourceLink identify the feed an article came from,
+	// for templates that group or attribute by source (e.g. openring mode).
+	SourceTitle string
+	SourceLink  string
+
+	// Category and Tags carry a feed's FeedSpec metadata onto each of its
+	// articles, for the "category"/"tag" GroupBy modes.
+	Category string
+	Tags     []string
+
+	// Author is the article's byline, when its feed format carries one
+	// (currently only JSON Feed's authors[0].name). Empty otherwise.
+	Author string
+
+	// Summary is a tag-stripped, length-capped plain-text rendering of the
+	// article's description/content. It's a plain string (not
+	// template.HTML) so html/template contextually escapes it on render,
+	// rather than trusting it as pre-sanitized markup.
+	Summary string
 }
 
 func main() {
+	importPath := flag.String("import", "", "Import feeds from an OPML file into config.yaml")
+	exportPath := flag.String("export", "", "Export config.yaml's feeds to an OPML file")
+	flag.Parse()
+
 	log.Printf("Paperboy v.%s (build %s)", version, build)
 
 	// Read YAML configuration file
@@ -66,46 +139,74 @@ func main() {
 		log.Fatalf("Error parsing config.yaml file: %v", err)
 	}
 
+	if *importPath != "" {
+		if err := importOPML(*importPath, &config); err != nil {
+			log.Fatalf("Error importing OPML file %s: %v", *importPath, err)
+		}
+		if err := updateFeedsInPlace(configFile, configData, config.Feeds); err != nil {
+			log.Fatalf("Error writing %s: %v", configFile, err)
+		}
+		log.Printf("-> Imported feeds from %s into %s", *importPath, configFile)
+		return
+	}
+
+	if *exportPath != "" {
+		if err := exportOPML(*exportPath, config); err != nil {
+			log.Fatalf("Error exporting OPML file %s: %v", *exportPath, err)
+		}
+		log.Printf("-> Exported feeds from %s to %s", configFile, *exportPath)
+		return
+	}
+
 	log.Printf("Feeds: %d", len(config.Feeds))
 
-	// Fetch articles from each feed URL
-	articlesByWeek := make(map[string][]Article)
-	var weeks []string
+	cacheFile := config.CacheFile
+	if cacheFile == "" {
+		cacheFile = defaultCacheFile
+	}
 
-	for _, feedURL := range config.Feeds {
+	cache, err := loadCache(cacheFile)
+	if err != nil {
+		log.Fatalf("Error loading feed cache from %s: %v", cacheFile, err)
+	}
 
-		log.Printf("Fetching articles from %s", feedURL)
+	// Fetch articles from each feed URL, in parallel, honoring per-feed
+	// conditional-GET and TTL state from the previous run.
+	var allArticles []Article
 
-		articles, err := fetchArticles(feedURL)
-		if err != nil {
-			log.Printf("Error fetching articles from %s: %v", feedURL, err)
+	for _, result := range fetchAll(config, cache) {
+		if result.err != nil {
+			log.Printf("Error fetching articles from %s: %v", result.url, result.err)
 			continue
 		}
 
-		log.Printf("-> Got %d articles", len(articles))
-
-		// Group articles by publish week
-		for _, article := range articles {
-			year, week := article.PublishAt.UTC().ISOWeek()
-			// Format week in the format "YYYY-WW"
-			// e.g. 2021-01
-			id := fmt.Sprintf("%d-%02d", year, week)
-			articlesByWeek[id] = append(articlesByWeek[id], article)
-
-			if !slices.Contains(weeks, id) {
-				weeks = append(weeks, id)
-			}
+		if result.skipped {
+			log.Printf("-> %s not modified, skipping", result.url)
+		} else {
+			log.Printf("-> Got %d articles from %s", len(result.articles), result.url)
+			cache[result.url] = result.entry
 		}
+
+		allArticles = append(allArticles, result.articles...)
 	}
 
-	// Sort weeks
-	sort.Strings(weeks)
-	slices.Reverse(weeks)
+	if err := cache.save(cacheFile); err != nil {
+		log.Printf("Error saving feed cache to %s: %v", cacheFile, err)
+	}
 
-	log.Printf("-> Sorted and reversed %d weeks", len(weeks))
+	var output string
+	if config.OpenRing.Enabled {
+		output, err = generateOpenring(config.OpenRing, allArticles)
+		if err != nil {
+			log.Fatalf("Error generating openring output: %v", err)
+		}
+	} else {
+		groups, ids := groupArticles(config.GroupBy, allArticles)
+		log.Printf("-> Grouped into %d groups", len(ids))
 
-	// Generate Markdown output
-	output := generateMarkdown(config.Template, articlesByWeek, weeks)
+		// Generate Markdown output
+		output = generateMarkdown(config.Template, config.GroupBy, groups, ids)
+	}
 
 	log.Printf("-> Generated Markdown output")
 
@@ -120,33 +221,65 @@ func main() {
 	log.Printf("Paperboy finished")
 }
 
-// fetchArticles fetches articles from a given feed URL
-func fetchArticles(feedURL string) ([]Article, error) {
-	fp := gofeed.NewParser()
-	feed, err := fp.ParseURL(feedURL)
-	if err != nil {
-		return nil, fmt.Errorf("error fetching feed: %v", err)
+// fetchAll fetches every feed in config.Feeds concurrently, using up to
+// config.Concurrency worker goroutines, and returns one fetchResult per
+// feed. Results are not returned in feed order.
+func fetchAll(config Config, cache FeedCache) []fetchResult {
+	concurrency := config.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
 	}
 
-	var articles []Article
-	for _, item := range feed.Items {
-		// Parse publish date
-		publishAt := item.PublishedParsed.UTC()
-		articleDomain := getURLDomain(item.Link)
+	client := &http.Client{Timeout: feedTimeout}
 
-		articles = append(articles, Article{
-			Title:     item.Title,
-			URL:       item.Link,
-			PublishAt: publishAt,
-			URLDomain: articleDomain,
-		})
+	jobs := make(chan FeedSpec)
+	results := make(chan fetchResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for spec := range jobs {
+				prev := cache[spec.URL]
+
+				if !cache.dueForFetch(spec.URL, time.Now().UTC()) {
+					articles := applyFeedSpec(slices.Clone(prev.Articles), spec)
+					results <- fetchResult{url: spec.URL, articles: articles, entry: prev, skipped: true}
+					continue
+				}
+
+				articles, entry, skipped, err := fetchArticles(client, spec.URL, prev)
+				articles = applyFeedSpec(slices.Clone(articles), spec)
+				results <- fetchResult{url: spec.URL, articles: articles, entry: entry, skipped: skipped, err: err}
+			}
+		}()
+	}
+
+	go func() {
+		for _, spec := range config.Feeds {
+			jobs <- spec
+		}
+		close(jobs)
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	all := make([]fetchResult, 0, len(config.Feeds))
+	for result := range results {
+		all = append(all, result)
 	}
 
-	return articles, nil
+	return all
 }
 
-// generateMarkdown generates Markdown output with header and footer
-func generateMarkdown(templateFile string, articlesByWeek map[string][]Article, weeks []string) string {
+// generateMarkdown generates Markdown output with header and footer,
+// rendering each group (in the order given by ids) under a heading
+// appropriate to groupBy.
+func generateMarkdown(templateFile, groupBy string, groups map[string][]Article, ids []string) string {
 	// Read template file
 	templateData, err := os.ReadFile(templateFile)
 	if err != nil {
@@ -167,20 +300,20 @@ func generateMarkdown(templateFile string, articlesByWeek map[string][]Article,
 	output.WriteString(header)
 	output.WriteString("\n\n")
 
-	for _, week := range weeks {
-		articles := articlesByWeek[week]
+	for _, id := range ids {
+		articles := groups[id]
 		if len(articles) == 0 {
 			continue
 		}
 
 		// Sort articles by publish date
 		slices.SortFunc(articles, func(a, b Article) int {
-			return cmp.Compare(a.PublishAt.Unix(), b.PublishAt.Unix())
+			return cmp.Compare(a.Date.Unix(), b.Date.Unix())
 		})
 
-		output.WriteString(fmt.Sprintf("## Week: %s\n\n", week))
+		output.WriteString(groupHeading(groupBy, id))
 		for _, article := range articles {
-			output.WriteString(fmt.Sprintf("- %s @ %s: [%s](%s)\n", article.PublishAt.Format("2006-01-02"), article.URLDomain, article.Title, article.URL))
+			output.WriteString(fmt.Sprintf("- %s @ %s: [%s](%s)\n", article.Date.Format("2006-01-02"), article.URLDomain, article.Title, article.Link))
 		}
 		output.WriteString("\n")
 
@@ -0,0 +1,120 @@
+// Copyright 2024 Ismo Vuorinen. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+// SPDX-License-Identifier: MIT
+
+//go:build go1.22
+// +build go1.22
+
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"html/template"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultSummaryLength is used when OpenRingConfig.SummaryLength is unset.
+const defaultSummaryLength = 256
+
+// defaultOpenRingCount is used when OpenRingConfig.Count is unset, matching
+// the other OpenRingConfig knobs (SummaryLength, and main.go's Concurrency/
+// CacheFile) in defaulting rather than silently producing an empty digest.
+const defaultOpenRingCount = 10
+
+// htmlCommentPattern matches HTML comments in full, closing only on "-->"
+// rather than the first '>' — comments may legitimately contain one (e.g.
+// conditional-comment markup like "<!--[if gt IE 6]>"). Stripped before
+// htmlTagPattern, which would otherwise truncate at that embedded '>' and
+// leave the rest of the comment's text behind.
+var htmlCommentPattern = regexp.MustCompile(`(?s)<!--.*?-->`)
+
+// htmlTagPattern matches HTML/XML tags and doctype/CDATA-style markup (an
+// opening '<' immediately followed by an optional '/' and a tag-name
+// character, or by '!'), used to strip markup from feed descriptions
+// before they're treated as plain text. Requiring a tag-name character (or
+// '!') after '<' keeps it from eating ordinary prose that happens to
+// contain '<'/'>', e.g. "x < 10 and y > 3".
+var htmlTagPattern = regexp.MustCompile(`(?s)</?[a-zA-Z!][^>]*>`)
+
+// generateOpenring renders the openring-style digest: the N most recent
+// articles across all feeds, capped at P per source, through an
+// html/template file.
+func generateOpenring(config OpenRingConfig, articles []Article) (string, error) {
+	summaryLength := config.SummaryLength
+	if summaryLength <= 0 {
+		summaryLength = defaultSummaryLength
+	}
+	for i := range articles {
+		articles[i].Summary = sanitizeSummary(articles[i].Summary, summaryLength)
+	}
+
+	count := config.Count
+	if count <= 0 {
+		count = defaultOpenRingCount
+	}
+
+	selected := selectOpenringArticles(articles, count, config.PerSource)
+
+	tmpl, err := template.ParseFiles(config.Template)
+	if err != nil {
+		return "", fmt.Errorf("error parsing openring template: %w", err)
+	}
+
+	var out strings.Builder
+	data := struct{ Articles []Article }{Articles: selected}
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("error rendering openring template: %w", err)
+	}
+
+	return out.String(), nil
+}
+
+// selectOpenringArticles sorts articles by publish date descending, then
+// greedily picks items while enforcing a per-source cap, until count
+// articles are chosen (or the input is exhausted).
+func selectOpenringArticles(articles []Article, count, perSource int) []Article {
+	sorted := slices.Clone(articles)
+	slices.SortFunc(sorted, func(a, b Article) int {
+		return cmp.Compare(b.Date.Unix(), a.Date.Unix())
+	})
+
+	perSourceCount := make(map[string]int)
+	selected := make([]Article, 0, count)
+
+	for _, article := range sorted {
+		if len(selected) >= count {
+			break
+		}
+		if perSource > 0 && perSourceCount[article.SourceLink] >= perSource {
+			continue
+		}
+
+		selected = append(selected, article)
+		perSourceCount[article.SourceLink]++
+	}
+
+	return selected
+}
+
+// sanitizeSummary strips HTML tags from html, collapses whitespace, and
+// truncates the result to at most maxRunes runes on a rune boundary
+// (safe for multi-byte CJK text), appending an ellipsis when truncated. The
+// result is a plain string: templates that embed it rely on html/template's
+// contextual auto-escaping rather than any guarantee made here.
+func sanitizeSummary(html string, maxRunes int) string {
+	text := htmlCommentPattern.ReplaceAllString(html, "")
+	text = htmlTagPattern.ReplaceAllString(text, "")
+	text = strings.Join(strings.Fields(text), " ")
+
+	if utf8.RuneCountInString(text) <= maxRunes {
+		return text
+	}
+
+	runes := []rune(text)
+	return string(runes[:maxRunes]) + "…"
+}
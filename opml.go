@@ -0,0 +1,225 @@
+// Copyright 2024 Ismo Vuorinen. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+// SPDX-License-Identifier: MIT
+
+//go:build go1.22
+// +build go1.22
+
+package main
+
+import (
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// opmlDocument is the root element of an OPML 2.0 document
+// (http://opml.org/spec2.opml).
+type opmlDocument struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    opmlHead `xml:"head"`
+	Body    opmlBody `xml:"body"`
+}
+
+// opmlHead holds the document title.
+type opmlHead struct {
+	Title string `xml:"title,omitempty"`
+}
+
+// opmlBody holds the top-level outlines.
+type opmlBody struct {
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+// opmlOutline is a single feed entry, or a folder of them (outlines may
+// nest arbitrarily in OPML).
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr,omitempty"`
+	Type     string        `xml:"type,attr,omitempty"`
+	XMLURL   string        `xml:"xmlUrl,attr,omitempty"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+// importOPML reads an OPML file and merges its feed URLs into
+// config.Feeds, deduplicated, preserving the existing order and appending
+// newly discovered feeds.
+func importOPML(path string, config *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading OPML file: %w", err)
+	}
+
+	var doc opmlDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("error parsing OPML file: %w", err)
+	}
+
+	existing := make(map[string]bool, len(config.Feeds))
+	for _, spec := range config.Feeds {
+		existing[spec.URL] = true
+	}
+
+	for _, outline := range flattenOutlines(doc.Body.Outlines) {
+		if outline.XMLURL == "" || existing[outline.XMLURL] {
+			continue
+		}
+
+		if !hasAllowedFeedScheme(outline.XMLURL) {
+			log.Printf("Skipping OPML outline %q: xmlUrl %q has no http(s):// or file:// scheme", cmp.Or(outline.Title, outline.Text), outline.XMLURL)
+			continue
+		}
+
+		config.Feeds = append(config.Feeds, FeedSpec{URL: outline.XMLURL, Title: cmp.Or(outline.Title, outline.Text)})
+		existing[outline.XMLURL] = true
+	}
+
+	return nil
+}
+
+// hasAllowedFeedScheme reports whether rawURL carries an http(s):// or an
+// explicit file:// scheme. localFeedPath treats any feed string without
+// "://" as a local file path, so an OPML outline's xmlUrl must be rejected
+// unless it names a scheme explicitly, otherwise a malformed or relative
+// xmlUrl could turn into an arbitrary local-file read on every run.
+func hasAllowedFeedScheme(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	switch u.Scheme {
+	case "http", "https", "file":
+		return true
+	default:
+		return false
+	}
+}
+
+// flattenOutlines walks a (possibly nested) outline tree and returns every
+// outline that carries a feed URL.
+func flattenOutlines(outlines []opmlOutline) []opmlOutline {
+	var flat []opmlOutline
+	for _, outline := range outlines {
+		if outline.XMLURL != "" {
+			flat = append(flat, outline)
+		}
+		flat = append(flat, flattenOutlines(outline.Outlines)...)
+	}
+
+	return flat
+}
+
+// exportOPML writes an OPML 2.0 document listing every feed in
+// config.Feeds, using each feed's discovered title (fetched lazily and
+// cached in config.CacheFile) as the outline's text/title.
+func exportOPML(path string, config Config) error {
+	cacheFile := config.CacheFile
+	if cacheFile == "" {
+		cacheFile = defaultCacheFile
+	}
+
+	cache, err := loadCache(cacheFile)
+	if err != nil {
+		return fmt.Errorf("error loading feed cache from %s: %v", cacheFile, err)
+	}
+
+	client := &http.Client{Timeout: feedTimeout}
+
+	doc := opmlDocument{
+		Version: "2.0",
+		Head:    opmlHead{Title: "Paperboy feeds"},
+	}
+
+	for _, spec := range config.Feeds {
+		title := cmp.Or(spec.Title, feedTitle(client, spec.URL, cache))
+		doc.Body.Outlines = append(doc.Body.Outlines, opmlOutline{
+			Text:   title,
+			Title:  title,
+			Type:   "rss",
+			XMLURL: spec.URL,
+		})
+	}
+
+	if err := cache.save(cacheFile); err != nil {
+		log.Printf("Error saving feed cache to %s: %v", cacheFile, err)
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding OPML document: %w", err)
+	}
+
+	return os.WriteFile(path, append([]byte(xml.Header), data...), 0644)
+}
+
+// feedTitle returns a feed's title, from cache.CacheEntry.Title when
+// known, otherwise fetching the feed once (updating cache) to discover it.
+// Feeds that can't be fetched fall back to their URL.
+func feedTitle(client *http.Client, feedURL string, cache FeedCache) string {
+	if entry, ok := cache[feedURL]; ok && entry.Title != "" {
+		return entry.Title
+	}
+
+	_, entry, _, err := fetchArticles(client, feedURL, cache[feedURL])
+	if err != nil {
+		log.Printf("Error fetching %s to discover its title: %v", feedURL, err)
+		return feedURL
+	}
+
+	cache[feedURL] = entry
+	if entry.Title != "" {
+		return entry.Title
+	}
+
+	return feedURL
+}
+
+// updateFeedsInPlace patches only the top-level "feeds" key of the YAML
+// document in original and writes the result to path, leaving every other
+// key, comment, and blank line untouched. This avoids re-marshaling the
+// whole Config struct, which would clobber any hand-written formatting in
+// the user's config.yaml.
+func updateFeedsInPlace(path string, original []byte, feeds []FeedSpec) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(original, &doc); err != nil {
+		return fmt.Errorf("error parsing config for in-place update: %w", err)
+	}
+
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("config.yaml is not a YAML mapping")
+	}
+	root := doc.Content[0]
+
+	var feedsNode yaml.Node
+	if err := feedsNode.Encode(feeds); err != nil {
+		return fmt.Errorf("error encoding feeds: %w", err)
+	}
+
+	replaced := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "feeds" {
+			root.Content[i+1] = &feedsNode
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "feeds"}, &feedsNode)
+	}
+
+	data, err := yaml.Marshal(&doc)
+	if err != nil {
+		return fmt.Errorf("error encoding config: %w", err)
+	}
+
+	return os.WriteFile(path, data, 0644)
+}